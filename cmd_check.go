@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var checkCommand = &cli.Command{
+	Name:  "check",
+	Usage: "exit 0 if an instance type has capacity available, non-zero otherwise",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "type", Required: true, Usage: "instance type name"},
+		&cli.StringFlag{Name: "region", Usage: "require capacity in this specific region"},
+	},
+	Action: func(c *cli.Context) error {
+		instanceTypes, err := newClient(c).GetInstanceTypes()
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("fetching instance types: %v", err), 2)
+		}
+
+		entry := WatchEntry{InstanceTypeName: c.String("type"), Region: c.String("region")}
+		region, err := checkInstanceAvailability(instanceTypes, entry)
+		if err != nil {
+			return cli.Exit(err.Error(), 2)
+		}
+
+		if region == "" {
+			fmt.Printf("%s: no capacity available\n", entry.InstanceTypeName)
+			return cli.Exit("", 1)
+		}
+
+		fmt.Printf("%s: available in %s\n", entry.InstanceTypeName, region)
+		return nil
+	},
+}
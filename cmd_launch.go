@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/yekta/lambdalabs-bot/lambda"
+)
+
+var launchCommand = &cli.Command{
+	Name:  "launch",
+	Usage: "launch an instance once, without polling for capacity",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "type", Required: true, Usage: "instance type name"},
+		&cli.StringFlag{Name: "region", Required: true, Usage: "region to launch in"},
+		&cli.StringFlag{Name: "ssh-key", Required: true, Usage: "SSH key name to attach"},
+		&cli.IntFlag{Name: "quantity", Value: 1, Usage: "number of instances to launch"},
+		dryRunFlag,
+	},
+	Action: func(c *cli.Context) error {
+		payload := lambda.LaunchPayload{
+			RegionName:       c.String("region"),
+			InstanceTypeName: c.String("type"),
+			SSHKeyNames:      []string{c.String("ssh-key")},
+			Quantity:         c.Int("quantity"),
+		}
+
+		if c.Bool("dry-run") {
+			out, err := json.MarshalIndent(payload, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Would POST to instance-operations/launch:\n%s\n", out)
+			return nil
+		}
+
+		result, err := newClient(c).LaunchInstance(payload)
+		if err != nil {
+			return fmt.Errorf("launching instance: %w", err)
+		}
+
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	},
+}
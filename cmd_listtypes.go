@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var listTypesCommand = &cli.Command{
+	Name:  "list-types",
+	Usage: "print available instance types and their regions with capacity as JSON",
+	Action: func(c *cli.Context) error {
+		client := newClient(c)
+
+		instanceTypes, err := client.GetInstanceTypes()
+		if err != nil {
+			return fmt.Errorf("fetching instance types: %w", err)
+		}
+
+		out, err := json.MarshalIndent(instanceTypes, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	},
+}
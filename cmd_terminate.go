@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var terminateCommand = &cli.Command{
+	Name:  "terminate",
+	Usage: "terminate an instance by ID",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "id", Required: true, Usage: "instance ID to terminate"},
+		dryRunFlag,
+	},
+	Action: func(c *cli.Context) error {
+		instanceID := c.String("id")
+
+		if c.Bool("dry-run") {
+			fmt.Printf("Would POST to instance-operations/terminate: %s\n", instanceID)
+			return nil
+		}
+
+		result, err := newClient(c).TerminateInstance(instanceID)
+		if err != nil {
+			return fmt.Errorf("terminating instance %s: %w", instanceID, err)
+		}
+
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	},
+}
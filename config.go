@@ -0,0 +1,61 @@
+package main
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/yekta/lambdalabs-bot/lambda"
+)
+
+// globalFlags apply to every subcommand: they configure the Lambda Cloud
+// API client itself, rather than any one operation against it.
+var globalFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "lambda-api-key",
+		EnvVars: []string{"LAMBDA_API_KEY"},
+		Usage:   "Lambda Cloud API key",
+	},
+	&cli.Float64Flag{
+		Name:    "api-calls-per-second",
+		EnvVars: []string{"API_CALLS_PER_SECOND"},
+		Value:   2,
+		Usage:   "rate limit applied to calls against the Lambda Cloud API",
+	},
+	&cli.IntFlag{
+		Name:    "api-max-attempts",
+		EnvVars: []string{"API_MAX_ATTEMPTS"},
+		Value:   5,
+		Usage:   "max attempts for a retryable API failure before giving up",
+	},
+	&cli.IntFlag{
+		Name:    "api-base-delay-seconds",
+		EnvVars: []string{"API_BASE_DELAY_SECONDS"},
+		Value:   1,
+		Usage:   "base delay for exponential backoff between retries",
+	},
+	&cli.IntFlag{
+		Name:    "api-max-delay-seconds",
+		EnvVars: []string{"API_MAX_DELAY_SECONDS"},
+		Value:   30,
+		Usage:   "cap on the exponential backoff delay between retries",
+	},
+}
+
+// dryRunFlag is added to every command that makes a mutating API call, so
+// it prints the payload it would send instead of sending it.
+var dryRunFlag = &cli.BoolFlag{
+	Name:  "dry-run",
+	Usage: "print the payload that would be sent instead of calling the API",
+}
+
+// newClient builds a lambda.Client from the global flags.
+func newClient(c *cli.Context) *lambda.Client {
+	return lambda.NewClient(
+		c.String("lambda-api-key"),
+		c.Float64("api-calls-per-second"),
+		c.Int("api-max-attempts"),
+		time.Duration(c.Int("api-base-delay-seconds"))*time.Second,
+		time.Duration(c.Int("api-max-delay-seconds"))*time.Second,
+	)
+}
@@ -0,0 +1,277 @@
+// Package lambda is a small client for the Lambda Cloud API
+// (https://cloud.lambdalabs.com/api/v1/), shared by every piece of the bot
+// that needs to check capacity or launch instances. It enforces a
+// token-bucket rate limit and retries retryable failures with exponential
+// backoff and jitter, similar to the client-side rate limiting worker
+// fleets use against the GCE API.
+package lambda
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/yekta/lambdalabs-bot/status"
+)
+
+const defaultBaseURL = "https://cloud.lambdalabs.com/api/v1/"
+
+// APIError is returned for any non-2xx response from the Lambda Cloud API.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("lambda api: status %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether this error is worth retrying. 401/403/422 mean
+// the request itself is broken (bad key, bad payload) and will never
+// succeed by retrying, so callers should fail fast instead.
+func (e *APIError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity:
+		return false
+	}
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Client is a rate-limited, retrying HTTP client for the Lambda Cloud API.
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	limiter    *tokenBucket
+
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+
+	queueDepth    int64
+	lastWaitNanos int64
+}
+
+// NewClient builds a Client rate-limited to callsPerSecond requests/sec,
+// retrying retryable failures up to maxAttempts times with exponential
+// backoff between baseDelay and maxDelay.
+func NewClient(apiKey string, callsPerSecond float64, maxAttempts int, baseDelay, maxDelay time.Duration) *Client {
+	if callsPerSecond <= 0 {
+		callsPerSecond = 2
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if baseDelay <= 0 {
+		baseDelay = time.Second
+	}
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+
+	return &Client{
+		apiKey:      apiKey,
+		baseURL:     defaultBaseURL,
+		httpClient:  &http.Client{},
+		limiter:     newTokenBucket(callsPerSecond),
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+		maxDelay:    maxDelay,
+	}
+}
+
+// QueueDepth is the number of calls currently blocked waiting on the rate
+// limiter. Intended for exposing as a gauge metric.
+func (c *Client) QueueDepth() int64 {
+	return atomic.LoadInt64(&c.queueDepth)
+}
+
+// LastWait is the most recent delay a call spent either waiting on the rate
+// limiter or backing off after a retryable failure.
+func (c *Client) LastWait() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.lastWaitNanos))
+}
+
+func (c *Client) GetInstanceTypes() (InstanceTypes, error) {
+	start := time.Now()
+	defer func() {
+		status.CapacityCheckDuration.Observe(time.Since(start).Seconds())
+	}()
+
+	var instanceTypes InstanceTypes
+	err := c.doWithRetry("instance-types", func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", c.baseURL+"instance-types", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(c.apiKey, "")
+		return req, nil
+	}, &instanceTypes)
+	return instanceTypes, err
+}
+
+func (c *Client) LaunchInstance(payload LaunchPayload) (interface{}, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	err = c.doWithRetry("instance-operations/launch", func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", c.baseURL+"instance-operations/launch", bytes.NewReader(jsonPayload))
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(c.apiKey, "")
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, &result)
+
+	if err != nil {
+		status.LaunchAttemptsTotal.WithLabelValues("failure").Inc()
+	} else {
+		status.LaunchAttemptsTotal.WithLabelValues("success").Inc()
+	}
+	return result, err
+}
+
+// TerminateInstance terminates a single instance by ID via
+// POST /instance-operations/terminate.
+func (c *Client) TerminateInstance(instanceID string) (interface{}, error) {
+	jsonPayload, err := json.Marshal(map[string][]string{"instance_ids": {instanceID}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	err = c.doWithRetry("instance-operations/terminate", func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", c.baseURL+"instance-operations/terminate", bytes.NewReader(jsonPayload))
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(c.apiKey, "")
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, &result)
+	return result, err
+}
+
+// ListInstances returns every instance on the account, as reported by
+// GET /instance-operations/list. Callers poll this to find out when a
+// just-launched instance has transitioned to "active" and learn its IP.
+func (c *Client) ListInstances() ([]Instance, error) {
+	var list instanceList
+	err := c.doWithRetry("instance-operations/list", func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", c.baseURL+"instance-operations/list", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(c.apiKey, "")
+		return req, nil
+	}, &list)
+	return list.Data, err
+}
+
+// doWithRetry builds and issues a fresh request (via buildReq) for each
+// attempt, throttling on the token bucket beforehand and backing off with
+// jitter between retryable failures. buildReq is called again on every
+// attempt so retries don't have to worry about an already-drained body.
+// endpoint is only used to label the lambdabot_api_requests_total metric.
+func (c *Client) doWithRetry(endpoint string, buildReq func() (*http.Request, error), out interface{}) error {
+	var lastErr error
+
+	for attempt := 0; attempt < c.maxAttempts; attempt++ {
+		c.throttle()
+
+		req, err := buildReq()
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			status.APIRequestsTotal.WithLabelValues(endpoint, "error").Inc()
+			lastErr = err
+			c.backoff(attempt, 0)
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			status.APIRequestsTotal.WithLabelValues(endpoint, "error").Inc()
+			lastErr = err
+			c.backoff(attempt, 0)
+			continue
+		}
+
+		status.APIRequestsTotal.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if out == nil {
+				return nil
+			}
+			return json.Unmarshal(body, out)
+		}
+
+		apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		if !apiErr.Retryable() {
+			return apiErr
+		}
+
+		lastErr = apiErr
+		c.backoff(attempt, retryAfter(resp.Header))
+	}
+
+	return lastErr
+}
+
+func (c *Client) throttle() {
+	wait := c.limiter.take()
+	if wait <= 0 {
+		return
+	}
+	atomic.AddInt64(&c.queueDepth, 1)
+	atomic.StoreInt64(&c.lastWaitNanos, int64(wait))
+	time.Sleep(wait)
+	atomic.AddInt64(&c.queueDepth, -1)
+}
+
+// backoff sleeps min(maxDelay, baseDelay*2^attempt) + rand[0, that], or
+// retryAfter if the server told us to wait longer than that.
+func (c *Client) backoff(attempt int, retryAfter time.Duration) {
+	delay := c.baseDelay * time.Duration(int64(1)<<uint(attempt))
+	if delay <= 0 || delay > c.maxDelay {
+		delay = c.maxDelay
+	}
+	delay += time.Duration(rand.Int63n(int64(delay) + 1))
+
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+
+	atomic.StoreInt64(&c.lastWaitNanos, int64(delay))
+	log.Printf("lambda api: retrying in %s (attempt %d/%d)", delay, attempt+1, c.maxAttempts)
+	time.Sleep(delay)
+}
+
+// retryAfter parses a Retry-After header given in seconds. It returns 0 if
+// the header is absent or not a plain integer.
+func retryAfter(header http.Header) time.Duration {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(v)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,50 @@
+package lambda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffStaysWithinBounds(t *testing.T) {
+	c := NewClient("key", 1000, 5, 5*time.Millisecond, 100*time.Millisecond)
+
+	for attempt := 0; attempt < 6; attempt++ {
+		start := time.Now()
+		c.backoff(attempt, 0)
+		elapsed := time.Since(start)
+
+		// base*2^attempt can double each attempt, but the jittered delay is
+		// always capped at maxDelay*2 (maxDelay plus up to 100% jitter).
+		if elapsed > 2*c.maxDelay {
+			t.Fatalf("attempt %d: backoff slept %v, want <= %v", attempt, elapsed, 2*c.maxDelay)
+		}
+	}
+}
+
+func TestBackoffHonorsRetryAfter(t *testing.T) {
+	c := NewClient("key", 1000, 5, time.Millisecond, 10*time.Millisecond)
+
+	start := time.Now()
+	c.backoff(0, 500*time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Fatalf("backoff with Retry-After slept %v, want >= 500ms", elapsed)
+	}
+}
+
+func TestLastWaitTracksThrottle(t *testing.T) {
+	c := NewClient("key", 2, 5, time.Second, time.Second)
+
+	// The first two calls are satisfied by the initial burst (capacity ==
+	// callsPerSecond); the third has to wait for a token to refill.
+	c.throttle()
+	c.throttle()
+	c.throttle()
+
+	if wait := c.LastWait(); wait <= 0 {
+		t.Fatalf("LastWait() after rate-limited throttle = %v, want > 0", wait)
+	}
+	// throttle() always decrements queueDepth back down before returning.
+	if depth := c.QueueDepth(); depth != 0 {
+		t.Fatalf("QueueDepth() after throttle returned = %d, want 0", depth)
+	}
+}
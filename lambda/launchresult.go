@@ -0,0 +1,27 @@
+package lambda
+
+// ExtractInstanceIDs pulls the launched instance IDs out of a
+// LaunchInstance result, which decodes the Lambda Cloud API's
+// {"data": {"instance_ids": [...]}} response shape into interface{}.
+func ExtractInstanceIDs(result interface{}) []string {
+	data, ok := result.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	inner, ok := data["data"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	rawIDs, ok := inner["instance_ids"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	ids := make([]string, 0, len(rawIDs))
+	for _, rawID := range rawIDs {
+		if id, ok := rawID.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
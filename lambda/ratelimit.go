@@ -0,0 +1,49 @@
+package lambda
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket is a simple token-bucket rate limiter: tokens refill
+// continuously at rate per second up to capacity, and take() reports how
+// long the caller must wait before a token is available.
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	capacity float64
+	rate     float64
+	last     time.Time
+}
+
+func newTokenBucket(ratePerSecond float64) *tokenBucket {
+	return &tokenBucket{
+		tokens:   ratePerSecond,
+		capacity: ratePerSecond,
+		rate:     ratePerSecond,
+		last:     time.Now(),
+	}
+}
+
+// take reserves a token, returning 0 if one was immediately available or
+// the duration the caller should sleep before proceeding otherwise.
+func (b *tokenBucket) take() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	b.tokens = 0
+	return wait
+}
@@ -0,0 +1,32 @@
+package lambda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketAllowsBurstUpToCapacity(t *testing.T) {
+	b := newTokenBucket(5)
+	for i := 0; i < 5; i++ {
+		if wait := b.take(); wait != 0 {
+			t.Fatalf("take() %d = %v, want 0 within initial burst", i, wait)
+		}
+	}
+	if wait := b.take(); wait <= 0 {
+		t.Fatalf("take() after burst = %v, want > 0", wait)
+	}
+}
+
+func TestTokenBucketRefillCapsAtCapacity(t *testing.T) {
+	b := newTokenBucket(2)
+	b.take()
+	b.take()
+	b.last = b.last.Add(-time.Hour)
+
+	if wait := b.take(); wait != 0 {
+		t.Fatalf("take() after long idle = %v, want 0", wait)
+	}
+	if b.tokens > b.capacity {
+		t.Fatalf("tokens = %v, want <= capacity %v", b.tokens, b.capacity)
+	}
+}
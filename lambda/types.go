@@ -0,0 +1,39 @@
+package lambda
+
+// InstanceTypes is the response body of GET /instance-types.
+type InstanceTypes struct {
+	Data map[string]InstanceTypeData `json:"data"`
+}
+
+type InstanceTypeData struct {
+	RegionsWithCapacityAvailable []Region `json:"regions_with_capacity_available"`
+}
+
+type Region struct {
+	Name string `json:"name"`
+}
+
+// LaunchPayload is the request body of POST /instance-operations/launch.
+type LaunchPayload struct {
+	RegionName       string   `json:"region_name"`
+	InstanceTypeName string   `json:"instance_type_name"`
+	SSHKeyNames      []string `json:"ssh_key_names"`
+	Quantity         int      `json:"quantity"`
+}
+
+// Instance is a single running or pending instance, as returned by
+// GET /instance-operations/list.
+type Instance struct {
+	ID           string          `json:"id"`
+	IP           string          `json:"ip"`
+	Status       string          `json:"status"`
+	InstanceType InstanceTypeRef `json:"instance_type"`
+}
+
+type InstanceTypeRef struct {
+	Name string `json:"name"`
+}
+
+type instanceList struct {
+	Data []Instance `json:"data"`
+}
@@ -0,0 +1,38 @@
+package notifier
+
+import "testing"
+
+func TestHTTPSURLPreservesPathAndQuery(t *testing.T) {
+	sinks, err := ParseSinks("webhook://example.com/hook?token=abc")
+	if err != nil {
+		t.Fatalf("ParseSinks: %v", err)
+	}
+	if len(sinks) != 1 {
+		t.Fatalf("expected 1 sink, got %d", len(sinks))
+	}
+
+	sink, ok := sinks[0].(*WebhookSink)
+	if !ok {
+		t.Fatalf("expected *WebhookSink, got %T", sinks[0])
+	}
+	want := "https://example.com/hook?token=abc"
+	if sink.url != want {
+		t.Errorf("sink url = %q, want %q", sink.url, want)
+	}
+}
+
+func TestParseSinksUnknownScheme(t *testing.T) {
+	if _, err := ParseSinks("ftp://example.com/hook"); err == nil {
+		t.Fatal("expected error for unknown scheme, got nil")
+	}
+}
+
+func TestParseSinksEmpty(t *testing.T) {
+	sinks, err := ParseSinks("")
+	if err != nil {
+		t.Fatalf("ParseSinks: %v", err)
+	}
+	if len(sinks) != 0 {
+		t.Errorf("expected no sinks, got %d", len(sinks))
+	}
+}
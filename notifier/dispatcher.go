@@ -0,0 +1,60 @@
+package notifier
+
+import (
+	"log"
+	"time"
+)
+
+// Dispatcher fans an Event out to every configured sink on its own
+// goroutine, reading off a bounded queue so a slow or hanging webhook can
+// never block the launcher loop that's sending events.
+type Dispatcher struct {
+	sinks []Sink
+	queue chan Event
+	done  chan struct{}
+}
+
+// NewDispatcher starts a Dispatcher with the given sinks and queue depth.
+func NewDispatcher(sinks []Sink, queueSize int) *Dispatcher {
+	if queueSize <= 0 {
+		queueSize = 32
+	}
+	d := &Dispatcher{
+		sinks: sinks,
+		queue: make(chan Event, queueSize),
+		done:  make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for e := range d.queue {
+		for _, sink := range d.sinks {
+			if err := sink.Notify(e); err != nil {
+				log.Printf("notifier: sink failed to deliver %s event: %v", e.Type, err)
+			}
+		}
+	}
+}
+
+// Send enqueues e for delivery, stamping its Time if unset. If the queue is
+// full the event is dropped and logged rather than blocking the caller.
+func (d *Dispatcher) Send(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	select {
+	case d.queue <- e:
+	default:
+		log.Printf("notifier: queue full, dropping %s event", e.Type)
+	}
+}
+
+// Close stops accepting new events and waits for in-flight sink calls to
+// finish delivering whatever is already queued.
+func (d *Dispatcher) Close() {
+	close(d.queue)
+	<-d.done
+}
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingSink never returns from Notify until release is closed, so a test
+// can hold the dispatcher's single worker goroutine busy while filling the
+// queue behind it.
+type blockingSink struct {
+	release chan struct{}
+	mu      sync.Mutex
+	got     []Event
+}
+
+func (s *blockingSink) Notify(e Event) error {
+	<-s.release
+	s.mu.Lock()
+	s.got = append(s.got, e)
+	s.mu.Unlock()
+	return nil
+}
+
+func TestDispatcherSendDropsWithoutBlockingWhenQueueFull(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	d := NewDispatcher([]Sink{sink}, 2)
+	defer func() {
+		close(sink.release)
+		d.Close()
+	}()
+
+	// The first Send is picked up by run()'s single worker and blocks there,
+	// so it doesn't count against queue capacity.
+	d.Send(Event{Type: CapacityFound})
+	time.Sleep(10 * time.Millisecond)
+
+	d.Send(Event{Type: LaunchSuccess})
+	d.Send(Event{Type: LaunchFailure})
+
+	done := make(chan struct{})
+	go func() {
+		d.Send(Event{Type: QuotaExhausted}) // queue is now full; must not block
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Send blocked instead of dropping the event when the queue was full")
+	}
+}
+
+func TestDispatcherDeliversToAllSinks(t *testing.T) {
+	var mu sync.Mutex
+	var delivered []Event
+	sink := sinkFunc(func(e Event) error {
+		mu.Lock()
+		delivered = append(delivered, e)
+		mu.Unlock()
+		return nil
+	})
+
+	d := NewDispatcher([]Sink{sink}, 4)
+	d.Send(Event{Type: LaunchSuccess, InstanceType: "gpu_1x_a6000"})
+	d.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(delivered) != 1 || delivered[0].InstanceType != "gpu_1x_a6000" {
+		t.Errorf("delivered = %+v, want one LaunchSuccess event for gpu_1x_a6000", delivered)
+	}
+}
+
+// sinkFunc adapts a plain function to the Sink interface for tests.
+type sinkFunc func(Event) error
+
+func (f sinkFunc) Notify(e Event) error { return f(e) }
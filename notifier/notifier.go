@@ -0,0 +1,63 @@
+// Package notifier fans out launcher events (capacity found, a launch
+// succeeding or failing, the watchlist quota being exhausted) to a set of
+// configured sinks: generic webhooks, Slack, Discord, and email.
+package notifier
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType categorizes a notifier Event.
+type EventType string
+
+const (
+	CapacityFound  EventType = "capacity_found"
+	LaunchSuccess  EventType = "launch_success"
+	LaunchFailure  EventType = "launch_failure"
+	QuotaExhausted EventType = "quota_exhausted"
+)
+
+// Event describes something a sink should notify about. Not every field is
+// set for every EventType — e.g. IP is only known once LaunchSuccess fires
+// a second time after the instance goes active.
+type Event struct {
+	Type         EventType
+	Message      string
+	InstanceType string
+	Region       string
+	InstanceID   string
+	IP           string
+	Err          error
+	Time         time.Time
+}
+
+// Text renders the event as a single human-readable line, used by every
+// sink that just wants a message string (Slack, Discord, SMTP subject/body).
+func (e Event) Text() string {
+	s := fmt.Sprintf("[%s]", e.Type)
+	if e.InstanceType != "" {
+		s += " " + e.InstanceType
+	}
+	if e.Region != "" {
+		s += " in " + e.Region
+	}
+	if e.InstanceID != "" {
+		s += " (instance " + e.InstanceID + ")"
+	}
+	if e.IP != "" {
+		s += " at " + e.IP
+	}
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Sink delivers a single notifier Event somewhere.
+type Sink interface {
+	Notify(Event) error
+}
@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func postJSON(client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notifier: %s returned status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// WebhookSink POSTs the event, JSON-encoded, to an arbitrary URL.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *WebhookSink) Notify(e Event) error {
+	return postJSON(s.client, s.url, map[string]interface{}{
+		"type":          string(e.Type),
+		"message":       e.Message,
+		"instance_type": e.InstanceType,
+		"region":        e.Region,
+		"instance_id":   e.InstanceID,
+		"ip":            e.IP,
+		"error":         errString(e.Err),
+		"time":          e.Time,
+	})
+}
+
+// SlackSink posts to a Slack incoming webhook URL.
+type SlackSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewSlackSink(url string) *SlackSink {
+	return &SlackSink{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *SlackSink) Notify(e Event) error {
+	return postJSON(s.client, s.url, map[string]string{"text": e.Text()})
+}
+
+// DiscordSink posts to a Discord webhook URL.
+type DiscordSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewDiscordSink(url string) *DiscordSink {
+	return &DiscordSink{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *DiscordSink) Notify(e Event) error {
+	return postJSON(s.client, s.url, map[string]string{"content": e.Text()})
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
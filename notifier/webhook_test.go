@@ -0,0 +1,55 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSinkPostsEventJSON(t *testing.T) {
+	var gotBody map[string]interface{}
+	var gotPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL + "/hook")
+	err := sink.Notify(Event{
+		Type:         LaunchSuccess,
+		InstanceType: "gpu_1x_a6000",
+		Region:       "us-east-1",
+		Message:      "launch requested",
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if gotPath != "/hook" {
+		t.Errorf("request path = %q, want /hook", gotPath)
+	}
+	if gotBody["type"] != string(LaunchSuccess) || gotBody["instance_type"] != "gpu_1x_a6000" {
+		t.Errorf("request body = %+v, want type=%s instance_type=gpu_1x_a6000", gotBody, LaunchSuccess)
+	}
+}
+
+func TestWebhookSinkReturnsErrorOnNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	if err := sink.Notify(Event{Type: LaunchFailure}); err == nil {
+		t.Fatal("Notify with a 500 response: want error, got nil")
+	}
+}
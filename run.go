@@ -0,0 +1,533 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/urfave/cli/v2"
+
+	"github.com/yekta/lambdalabs-bot/lambda"
+	"github.com/yekta/lambdalabs-bot/notifier"
+	"github.com/yekta/lambdalabs-bot/status"
+	"github.com/yekta/lambdalabs-bot/store"
+)
+
+// WatchEntry is a single (instance type, region, ssh key) combination the
+// launcher polls for, in priority order. Region is optional; when empty the
+// first region Lambda reports capacity in is used.
+type WatchEntry struct {
+	InstanceTypeName string
+	Region           string
+	SSHKeyName       string
+	Quantity         int
+}
+
+// Daemon-wide state, set up once in runCommand's Action and read by
+// launchInstanceLoop and notifyWhenActive for the life of the process.
+var (
+	watchlist          []WatchEntry
+	maxInstances       int
+	checkInterval      int
+	errorWait          int
+	port               int
+	activePollWait     time.Duration
+	activePollTries    int
+	maxLaunchesPerHour int
+	startingCount      int
+	dryRun             bool
+	client             *lambda.Client
+	recorder           = status.NewRecorder(200)
+	notifyDispatcher   *notifier.Dispatcher
+	dataStore          *store.Store
+)
+
+var runCommand = &cli.Command{
+	Name:  "run",
+	Usage: "poll Lambda Cloud capacity and launch instances off the watchlist until MAX_INSTANCES is reached",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "instance-type",
+			EnvVars: []string{"INSTANCE_TYPE_NAME"},
+			Value:   "gpu_1x_a6000",
+			Usage:   "comma-separated, priority-ordered instance types to watch",
+		},
+		&cli.StringFlag{
+			Name:    "region",
+			EnvVars: []string{"REGION"},
+			Usage:   "comma-separated regions, matched index-wise to --instance-type",
+		},
+		&cli.StringFlag{
+			Name:    "ssh-key",
+			EnvVars: []string{"SSH_KEY_NAME"},
+			Usage:   "comma-separated SSH key names, matched index-wise to --instance-type",
+		},
+		&cli.StringFlag{
+			Name:    "quantity",
+			EnvVars: []string{"QUANTITY"},
+			Usage:   "comma-separated per-entry launch quantities, matched index-wise to --instance-type",
+		},
+		&cli.IntFlag{
+			Name:    "max-instances",
+			EnvVars: []string{"MAX_INSTANCES"},
+			Value:   1,
+			Usage:   "keep polling until this many instances have been launched in total",
+		},
+		&cli.IntFlag{
+			Name:    "check-interval",
+			EnvVars: []string{"CHECK_INTERVAL_SECONDS"},
+			Value:   30,
+			Usage:   "seconds between capacity checks",
+		},
+		&cli.IntFlag{
+			Name:    "error-wait",
+			EnvVars: []string{"ERROR_WAIT_SECONDS"},
+			Value:   10,
+			Usage:   "seconds to wait after a retryable error before checking again",
+		},
+		&cli.IntFlag{
+			Name:    "port",
+			EnvVars: []string{"PORT"},
+			Value:   5000,
+			Usage:   "port to serve /status, /statusz and /metrics on",
+		},
+		&cli.IntFlag{
+			Name:    "active-poll-interval-seconds",
+			EnvVars: []string{"ACTIVE_POLL_INTERVAL_SECONDS"},
+			Value:   15,
+			Usage:   "seconds between polls of instance-operations/list while waiting for a launch to go active",
+		},
+		&cli.IntFlag{
+			Name:    "active-poll-max-attempts",
+			EnvVars: []string{"ACTIVE_POLL_MAX_ATTEMPTS"},
+			Value:   40,
+			Usage:   "give up waiting for a launch to go active after this many polls",
+		},
+		&cli.StringFlag{
+			Name:    "notify-urls",
+			EnvVars: []string{"NOTIFY_URLS"},
+			Usage:   "comma-separated notifier sink URLs, e.g. slack://..., webhook://..., discord://..., smtp://...",
+		},
+		&cli.StringFlag{
+			Name:    "store-path",
+			EnvVars: []string{"STORE_PATH"},
+			Value:   "lambdabot.db",
+			Usage:   "path to the BoltDB file recording launch and capacity history",
+		},
+		&cli.IntFlag{
+			Name:    "max-launches-per-hour",
+			EnvVars: []string{"MAX_LAUNCHES_PER_HOUR"},
+			Usage:   "cap on instances launched in any trailing hour, enforced from --store-path (0 = no cap)",
+		},
+		dryRunFlag,
+	},
+	Action: func(c *cli.Context) error {
+		client = newClient(c)
+		watchlist = buildWatchlist(c)
+		recorder.SetWatchlist(watchlist)
+
+		maxInstances = c.Int("max-instances")
+		checkInterval = c.Int("check-interval")
+		errorWait = c.Int("error-wait")
+		port = c.Int("port")
+		activePollWait = time.Duration(c.Int("active-poll-interval-seconds")) * time.Second
+		activePollTries = c.Int("active-poll-max-attempts")
+		maxLaunchesPerHour = c.Int("max-launches-per-hour")
+		dryRun = c.Bool("dry-run")
+
+		sinks, err := notifier.ParseSinks(c.String("notify-urls"))
+		if err != nil {
+			return fmt.Errorf("invalid --notify-urls: %w", err)
+		}
+		notifyDispatcher = notifier.NewDispatcher(sinks, 64)
+
+		dataStore, err = store.Open(c.String("store-path"))
+		if err != nil {
+			return fmt.Errorf("opening store at %s: %w", c.String("store-path"), err)
+		}
+
+		startingCount = reconcileLaunchedCount()
+		if startingCount > 0 {
+			recorder.Infof("Reconciled %d already-running instance(s) matching the watchlist on startup", startingCount)
+		}
+
+		runDaemon()
+		return nil
+	},
+}
+
+// reconcileLaunchedCount counts non-terminated instances on the account
+// whose type is on our watchlist, so a restart picks up where a previous
+// run left off instead of re-launching past MAX_INSTANCES.
+func reconcileLaunchedCount() int {
+	watchedTypes := make(map[string]bool, len(watchlist))
+	for _, entry := range watchlist {
+		watchedTypes[entry.InstanceTypeName] = true
+	}
+
+	instances, err := client.ListInstances()
+	if err != nil {
+		recorder.Warnf("Could not reconcile with instance-operations/list on startup: %v", err)
+		return 0
+	}
+
+	count := 0
+	for _, inst := range instances {
+		if inst.Status != "terminated" && watchedTypes[inst.InstanceType.Name] {
+			count++
+		}
+	}
+	return count
+}
+
+// launchBudgetRemaining reports how many more instances MAX_LAUNCHES_PER_HOUR
+// allows launching right now, per the store's audit log of the trailing
+// hour. It returns math.MaxInt32 (no cap) when the flag is unset or the
+// store can't be queried, matching the "fail open" posture of the rest of
+// the launcher's error handling.
+func launchBudgetRemaining() int {
+	if maxLaunchesPerHour <= 0 {
+		return math.MaxInt32
+	}
+	count, err := dataStore.InstancesLaunchedSince(time.Now().Add(-time.Hour))
+	if err != nil {
+		recorder.Warnf("Could not check launch budget: %v", err)
+		return math.MaxInt32
+	}
+	remaining := maxLaunchesPerHour - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining
+}
+
+// buildWatchlist assembles the ordered list of (type, region, ssh key)
+// combinations to poll from the comma-separated --instance-type, --region,
+// --ssh-key and --quantity flags. Shorter lists fall back to their
+// first/default entry so a single --ssh-key still applies to every
+// instance type.
+func buildWatchlist(c *cli.Context) []WatchEntry {
+	types := splitCSV(c.String("instance-type"))
+	if len(types) == 0 {
+		types = []string{"gpu_1x_a6000"}
+	}
+
+	regions := splitCSV(c.String("region"))
+	sshKeys := splitCSV(c.String("ssh-key"))
+	quantities := splitCSV(c.String("quantity"))
+
+	entries := make([]WatchEntry, len(types))
+	for i, instanceType := range types {
+		entry := WatchEntry{InstanceTypeName: instanceType, Quantity: 1}
+
+		if len(regions) == 1 {
+			entry.Region = regions[0]
+		} else if i < len(regions) {
+			entry.Region = regions[i]
+		}
+
+		if len(sshKeys) == 1 {
+			entry.SSHKeyName = sshKeys[0]
+		} else if i < len(sshKeys) {
+			entry.SSHKeyName = sshKeys[i]
+		}
+
+		if len(quantities) == 1 {
+			if q, err := strconv.Atoi(quantities[0]); err == nil && q > 0 {
+				entry.Quantity = q
+			}
+		} else if i < len(quantities) {
+			if q, err := strconv.Atoi(quantities[i]); err == nil && q > 0 {
+				entry.Quantity = q
+			}
+		}
+
+		entries[i] = entry
+	}
+
+	return entries
+}
+
+// checkInstanceAvailability returns the first available region for entry,
+// honoring entry.Region when it's pinned to one. An empty region means
+// nothing is available for this entry right now.
+func checkInstanceAvailability(instanceTypes lambda.InstanceTypes, entry WatchEntry) (string, error) {
+	data, exists := instanceTypes.Data[entry.InstanceTypeName]
+	if !exists || len(data.RegionsWithCapacityAvailable) == 0 {
+		return "", nil
+	}
+
+	if entry.Region == "" {
+		return data.RegionsWithCapacityAvailable[0].Name, nil
+	}
+
+	for _, region := range data.RegionsWithCapacityAvailable {
+		if region.Name == entry.Region {
+			return region.Name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// recordRegionsAvailable updates the lambdabot_regions_available gauge for
+// every instance type Lambda reported on this poll, not just the ones on
+// our watchlist, so operators can spot capacity opening up elsewhere. It
+// also appends a capacity snapshot to the store's audit log.
+func recordRegionsAvailable(instanceTypes lambda.InstanceTypes) {
+	available := make(map[string][]string, len(instanceTypes.Data))
+	for name, data := range instanceTypes.Data {
+		status.RegionsAvailable.WithLabelValues(name).Set(float64(len(data.RegionsWithCapacityAvailable)))
+
+		regions := make([]string, len(data.RegionsWithCapacityAvailable))
+		for i, region := range data.RegionsWithCapacityAvailable {
+			regions[i] = region.Name
+		}
+		available[name] = regions
+	}
+
+	if err := dataStore.RecordSnapshot(store.CapacitySnapshot{Time: time.Now(), Available: available}); err != nil {
+		recorder.Warnf("Could not record capacity snapshot: %v", err)
+	}
+}
+
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	records, err := dataStore.Launches()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+func capacityHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := dataStore.Snapshots()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshots)
+}
+
+func runDaemon() {
+	log.Println("Starting instance launcher script...")
+	recorder.SetState("running")
+
+	go launchInstanceLoop()
+	go sampleClientMetrics()
+
+	r := chi.NewRouter()
+	r.Use(middleware.Logger)
+	r.Get("/status", recorder.ServeHTML)
+	r.Get("/statusz", recorder.ServeJSON)
+	r.Get("/history", historyHandler)
+	r.Get("/capacity-history", capacityHistoryHandler)
+	r.Handle("/metrics", promhttp.Handler())
+
+	log.Printf("Listening on port %d\n", port)
+	log.Fatal(http.ListenAndServe("0.0.0.0:"+strconv.Itoa(port), r))
+}
+
+// sampleClientMetrics polls client's rate-limiter stats onto the
+// lambdabot_queue_depth/lambdabot_last_wait_seconds gauges, keeping the
+// lambda package itself free of any dependency on the status/Prometheus
+// machinery.
+func sampleClientMetrics() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		status.QueueDepth.Set(float64(client.QueueDepth()))
+		status.LastWaitSeconds.Set(client.LastWait().Seconds())
+	}
+}
+
+func launchInstanceLoop() {
+	launchedCount := startingCount
+	var results []interface{}
+
+	for launchedCount < maxInstances {
+		instanceTypes, err := client.GetInstanceTypes()
+		if err != nil {
+			if apiErr, ok := err.(*lambda.APIError); ok && !apiErr.Retryable() {
+				recorder.Errorf("Non-retryable error fetching instance types: %v. Will try again in %d seconds.", err, errorWait)
+			} else {
+				recorder.SetState("error")
+				recorder.Errorf("Error fetching instance types: %v. Retrying in %d seconds.", err, errorWait)
+			}
+			time.Sleep(time.Duration(errorWait) * time.Second)
+			continue
+		}
+		recordRegionsAvailable(instanceTypes)
+
+		launchedThisTick := false
+		for _, entry := range watchlist {
+			if launchedCount >= maxInstances {
+				break
+			}
+
+			regionName, err := checkInstanceAvailability(instanceTypes, entry)
+			if err != nil {
+				recorder.SetState("error")
+				recorder.Errorf("Error checking instance availability: %v. Retrying in %d seconds.", err, errorWait)
+				time.Sleep(time.Duration(errorWait) * time.Second)
+				continue
+			}
+
+			if regionName == "" {
+				recorder.Infof("No available regions found for %s. Checking again in %d seconds.", entry.InstanceTypeName, checkInterval)
+				continue
+			}
+
+			notifyDispatcher.Send(notifier.Event{
+				Type:         notifier.CapacityFound,
+				InstanceType: entry.InstanceTypeName,
+				Region:       regionName,
+			})
+
+			budgetRemaining := launchBudgetRemaining()
+			if budgetRemaining <= 0 {
+				recorder.Warnf("MAX_LAUNCHES_PER_HOUR (%d) reached; holding off on launching %s in %s.", maxLaunchesPerHour, entry.InstanceTypeName, regionName)
+				break
+			}
+
+			quantity := entry.Quantity
+			if remaining := maxInstances - launchedCount; remaining < quantity {
+				quantity = remaining
+			}
+			if budgetRemaining < quantity {
+				quantity = budgetRemaining
+			}
+			if quantity < entry.Quantity {
+				recorder.Infof("Clamping launch quantity for %s from %d to %d to stay within MAX_INSTANCES/MAX_LAUNCHES_PER_HOUR.", entry.InstanceTypeName, entry.Quantity, quantity)
+			}
+
+			payload := lambda.LaunchPayload{
+				RegionName:       regionName,
+				InstanceTypeName: entry.InstanceTypeName,
+				SSHKeyNames:      []string{entry.SSHKeyName},
+				Quantity:         quantity,
+			}
+
+			if dryRun {
+				out, _ := json.MarshalIndent(payload, "", "  ")
+				recorder.Infof("[dry-run] Would POST to instance-operations/launch:\n%s", out)
+				launchedCount += quantity
+				launchedThisTick = true
+				recorder.SetState("instance launched (dry-run)")
+				continue
+			}
+
+			result, err := client.LaunchInstance(payload)
+
+			rec := store.LaunchRecord{
+				Time:         time.Now(),
+				InstanceType: entry.InstanceTypeName,
+				Region:       regionName,
+				Request:      payload,
+			}
+			if err != nil {
+				rec.Error = err.Error()
+			} else {
+				rec.Response = result
+				rec.InstanceIDs = lambda.ExtractInstanceIDs(result)
+			}
+			if serr := dataStore.RecordLaunch(rec); serr != nil {
+				recorder.Warnf("Could not record launch attempt in store: %v", serr)
+			}
+
+			if err != nil {
+				notifyDispatcher.Send(notifier.Event{
+					Type:         notifier.LaunchFailure,
+					InstanceType: entry.InstanceTypeName,
+					Region:       regionName,
+					Err:          err,
+				})
+				if apiErr, ok := err.(*lambda.APIError); ok && !apiErr.Retryable() {
+					recorder.Errorf("Non-retryable error launching %s: %v. Skipping this entry.", entry.InstanceTypeName, err)
+					continue
+				}
+				recorder.SetState("error")
+				recorder.Errorf("Error launching instance: %v. Retrying in %d seconds.", err, errorWait)
+				time.Sleep(time.Duration(errorWait) * time.Second)
+				continue
+			}
+
+			recorder.Infof("Instance launch result: %v", result)
+			launchedCount += quantity
+			results = append(results, result)
+			launchedThisTick = true
+			recorder.SetState("instance launched")
+			recorder.SetLastResult(results)
+
+			notifyDispatcher.Send(notifier.Event{
+				Type:         notifier.LaunchSuccess,
+				InstanceType: entry.InstanceTypeName,
+				Region:       regionName,
+				Message:      "launch requested",
+			})
+			for _, id := range lambda.ExtractInstanceIDs(result) {
+				go notifyWhenActive(id, entry, regionName)
+			}
+		}
+
+		if launchedCount >= maxInstances {
+			break
+		}
+
+		if !launchedThisTick {
+			time.Sleep(time.Duration(checkInterval) * time.Second)
+		}
+	}
+
+	notifyDispatcher.Send(notifier.Event{
+		Type:    notifier.QuotaExhausted,
+		Message: fmt.Sprintf("reached MAX_INSTANCES (%d)", maxInstances),
+	})
+	recorder.Infof("Reached MAX_INSTANCES (%d). Launcher loop exiting.", maxInstances)
+}
+
+// notifyWhenActive polls instance-operations/list until instanceID
+// transitions to "active", then notifies with its IP. It gives up and logs
+// a warning after activePollTries attempts.
+func notifyWhenActive(instanceID string, entry WatchEntry, regionName string) {
+	for attempt := 0; attempt < activePollTries; attempt++ {
+		time.Sleep(activePollWait)
+
+		instances, err := client.ListInstances()
+		if err != nil {
+			recorder.Warnf("Error polling for instance %s activation: %v", instanceID, err)
+			continue
+		}
+
+		for _, inst := range instances {
+			if inst.ID != instanceID {
+				continue
+			}
+			if inst.Status != "active" {
+				break
+			}
+
+			recorder.Infof("Instance %s is active at %s", instanceID, inst.IP)
+			notifyDispatcher.Send(notifier.Event{
+				Type:         notifier.LaunchSuccess,
+				InstanceType: entry.InstanceTypeName,
+				Region:       regionName,
+				InstanceID:   instanceID,
+				IP:           inst.IP,
+				Message:      "instance active",
+			})
+			return
+		}
+	}
+
+	recorder.Warnf("Timed out waiting for instance %s to become active", instanceID)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/yekta/lambdalabs-bot/lambda"
+	"github.com/yekta/lambdalabs-bot/store"
+)
+
+// buildWatchlistFromArgs drives buildWatchlist through the same flags
+// runCommand registers, so the test exercises the real flag parsing instead
+// of constructing a *cli.Context by hand.
+func buildWatchlistFromArgs(t *testing.T, args ...string) []WatchEntry {
+	t.Helper()
+
+	var got []WatchEntry
+	app := &cli.App{
+		Name:  "test",
+		Flags: runCommand.Flags,
+		Action: func(c *cli.Context) error {
+			got = buildWatchlist(c)
+			return nil
+		},
+	}
+	if err := app.Run(append([]string{"test"}, args...)); err != nil {
+		t.Fatalf("app.Run: %v", err)
+	}
+	return got
+}
+
+func TestBuildWatchlistDefaultsToSingleEntry(t *testing.T) {
+	entries := buildWatchlistFromArgs(t)
+	if len(entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(entries))
+	}
+	if entries[0].InstanceTypeName != "gpu_1x_a6000" || entries[0].Quantity != 1 {
+		t.Errorf("entries[0] = %+v, want default gpu_1x_a6000 x1", entries[0])
+	}
+}
+
+func TestBuildWatchlistSingleValueAppliesToEveryType(t *testing.T) {
+	entries := buildWatchlistFromArgs(t,
+		"--instance-type", "gpu_1x_a6000,gpu_1x_a100",
+		"--region", "us-east-1",
+		"--ssh-key", "my-key",
+		"--quantity", "3",
+	)
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+	for i, e := range entries {
+		if e.Region != "us-east-1" || e.SSHKeyName != "my-key" || e.Quantity != 3 {
+			t.Errorf("entries[%d] = %+v, want single region/ssh-key/quantity applied to all", i, e)
+		}
+	}
+}
+
+func TestBuildWatchlistMatchesIndexWise(t *testing.T) {
+	entries := buildWatchlistFromArgs(t,
+		"--instance-type", "gpu_1x_a6000,gpu_1x_a100,gpu_8x_v100",
+		"--region", "us-east-1,us-west-1",
+		"--quantity", "2,5",
+	)
+	if len(entries) != 3 {
+		t.Fatalf("len(entries) = %d, want 3", len(entries))
+	}
+
+	if entries[0].Region != "us-east-1" || entries[0].Quantity != 2 {
+		t.Errorf("entries[0] = %+v, want region us-east-1, quantity 2", entries[0])
+	}
+	if entries[1].Region != "us-west-1" || entries[1].Quantity != 5 {
+		t.Errorf("entries[1] = %+v, want region us-west-1, quantity 5", entries[1])
+	}
+	// Shorter than --instance-type: falls back to the zero value, not the
+	// last entry in the list.
+	if entries[2].Region != "" || entries[2].Quantity != 1 {
+		t.Errorf("entries[2] = %+v, want zero-value region and default quantity 1", entries[2])
+	}
+}
+
+// withTestStore points the package-level dataStore/maxLaunchesPerHour at a
+// scratch BoltDB file for the duration of the test, restoring both after.
+func withTestStore(t *testing.T, maxPerHour int) {
+	t.Helper()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store.Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	prevStore, prevMax := dataStore, maxLaunchesPerHour
+	dataStore, maxLaunchesPerHour = s, maxPerHour
+	t.Cleanup(func() { dataStore, maxLaunchesPerHour = prevStore, prevMax })
+}
+
+func TestLaunchBudgetRemainingNoCap(t *testing.T) {
+	withTestStore(t, 0)
+	if got := launchBudgetRemaining(); got != math.MaxInt32 {
+		t.Errorf("launchBudgetRemaining() = %d, want math.MaxInt32 when MAX_LAUNCHES_PER_HOUR is unset", got)
+	}
+}
+
+func TestLaunchBudgetRemainingDecreasesWithRecordedLaunches(t *testing.T) {
+	withTestStore(t, 5)
+
+	if got := launchBudgetRemaining(); got != 5 {
+		t.Fatalf("launchBudgetRemaining() with no launches = %d, want 5", got)
+	}
+
+	if err := dataStore.RecordLaunch(store.LaunchRecord{
+		Time:        time.Now(),
+		Request:     lambda.LaunchPayload{Quantity: 3},
+		InstanceIDs: []string{"i-1", "i-2", "i-3"},
+	}); err != nil {
+		t.Fatalf("RecordLaunch: %v", err)
+	}
+
+	if got := launchBudgetRemaining(); got != 2 {
+		t.Errorf("launchBudgetRemaining() after launching 3 = %d, want 2", got)
+	}
+}
+
+func TestLaunchBudgetRemainingFloorsAtZero(t *testing.T) {
+	withTestStore(t, 2)
+
+	if err := dataStore.RecordLaunch(store.LaunchRecord{
+		Time:        time.Now(),
+		Request:     lambda.LaunchPayload{Quantity: 5},
+		InstanceIDs: []string{"i-1", "i-2", "i-3", "i-4", "i-5"},
+	}); err != nil {
+		t.Fatalf("RecordLaunch: %v", err)
+	}
+
+	if got := launchBudgetRemaining(); got != 0 {
+		t.Errorf("launchBudgetRemaining() over budget = %d, want 0, not negative", got)
+	}
+}
+
+func TestBuildWatchlistIgnoresInvalidQuantity(t *testing.T) {
+	entries := buildWatchlistFromArgs(t,
+		"--instance-type", "gpu_1x_a6000",
+		"--quantity", "not-a-number",
+	)
+	if len(entries) != 1 || entries[0].Quantity != 1 {
+		t.Fatalf("entries = %+v, want default quantity 1 when --quantity is unparseable", entries)
+	}
+}
@@ -0,0 +1,47 @@
+package status
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+)
+
+var dashboardTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<title>lambdalabs-bot status</title>
+	<meta charset="utf-8">
+</head>
+<body>
+	<h1>lambdalabs-bot</h1>
+	<p><strong>State:</strong> {{.State}}</p>
+	<p><strong>Uptime:</strong> {{.Uptime}}</p>
+
+	<h2>Watchlist</h2>
+	<pre>{{printf "%+v" .Watchlist}}</pre>
+
+	<h2>Last launch result</h2>
+	<pre>{{printf "%+v" .LastResult}}</pre>
+
+	<h2>Recent checks</h2>
+	<table border="1" cellpadding="4" cellspacing="0">
+		<tr><th>Time</th><th>Level</th><th>Message</th></tr>
+		{{range .Events}}
+		<tr><td>{{.Time.Format "2006-01-02 15:04:05"}}</td><td>{{.Level}}</td><td>{{.Message}}</td></tr>
+		{{end}}
+	</table>
+</body>
+</html>
+`))
+
+// ServeHTML renders the /status dashboard.
+func (r *Recorder) ServeHTML(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	dashboardTemplate.Execute(w, r.Snapshot())
+}
+
+// ServeJSON renders the /statusz JSON snapshot.
+func (r *Recorder) ServeJSON(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r.Snapshot())
+}
@@ -0,0 +1,42 @@
+package status
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus metrics exported on /metrics. These are package-level so both
+// the lambda API client and the launcher loop can record against them
+// without threading a metrics object through every call.
+var (
+	APIRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lambdabot_api_requests_total",
+		Help: "Total number of Lambda Cloud API requests, by endpoint and response code.",
+	}, []string{"endpoint", "code"})
+
+	CapacityCheckDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "lambdabot_capacity_check_duration_seconds",
+		Help:    "Time spent checking instance-type capacity on each poll.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	LaunchAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lambdabot_launch_attempts_total",
+		Help: "Total number of instance launch attempts, by result.",
+	}, []string{"result"})
+
+	RegionsAvailable = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lambdabot_regions_available",
+		Help: "Number of regions with capacity available for an instance type, as of the most recent poll.",
+	}, []string{"instance_type"})
+
+	QueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "lambdabot_queue_depth",
+		Help: "Number of Lambda Cloud API calls currently blocked waiting on the client's rate limiter.",
+	})
+
+	LastWaitSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "lambdabot_last_wait_seconds",
+		Help: "Most recent delay spent waiting on the rate limiter or backing off after a retryable failure.",
+	})
+)
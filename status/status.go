@@ -0,0 +1,170 @@
+// Package status is a small leveled status recorder for the launcher: it
+// keeps a bounded ring buffer of recent events (à la the Go build
+// coordinator's status log) plus the launcher's current watchlist and last
+// launch result, and renders them as an HTML dashboard or JSON.
+package status
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	Info Level = iota
+	Warn
+	Error
+)
+
+func (l Level) String() string {
+	switch l {
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+// Event is a single leveled, timestamped status line.
+type Event struct {
+	Time    time.Time
+	Level   Level
+	Message string
+}
+
+// Recorder keeps a bounded ring buffer of recent events plus the launcher's
+// current watchlist and last launch result, for the /status and /statusz
+// endpoints. It's safe for concurrent use.
+type Recorder struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	events    []Event
+	next      int
+	filled    bool
+
+	state      string
+	watchlist  interface{}
+	lastResult interface{}
+}
+
+// NewRecorder creates a Recorder holding up to capacity recent events.
+func NewRecorder(capacity int) *Recorder {
+	if capacity <= 0 {
+		capacity = 200
+	}
+	return &Recorder{
+		startedAt: time.Now(),
+		events:    make([]Event, capacity),
+		state:     "starting",
+	}
+}
+
+func (r *Recorder) record(level Level, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+
+	r.mu.Lock()
+	r.events[r.next] = Event{Time: time.Now(), Level: level, Message: msg}
+	r.next++
+	if r.next == len(r.events) {
+		r.next = 0
+		r.filled = true
+	}
+	r.mu.Unlock()
+
+	log.Printf("[%s] %s", level, msg)
+}
+
+func (r *Recorder) Infof(format string, args ...interface{})  { r.record(Info, format, args...) }
+func (r *Recorder) Warnf(format string, args ...interface{})  { r.record(Warn, format, args...) }
+func (r *Recorder) Errorf(format string, args ...interface{}) { r.record(Error, format, args...) }
+
+// SetState records the launcher's current high-level state, e.g. "running",
+// "error", or "instance launched".
+func (r *Recorder) SetState(state string) {
+	r.mu.Lock()
+	r.state = state
+	r.mu.Unlock()
+}
+
+// SetWatchlist records the current watchlist, rendered as-is on the
+// dashboard and in the JSON snapshot.
+func (r *Recorder) SetWatchlist(watchlist interface{}) {
+	r.mu.Lock()
+	r.watchlist = watchlist
+	r.mu.Unlock()
+}
+
+// SetLastResult records the most recent launch result(s).
+func (r *Recorder) SetLastResult(result interface{}) {
+	r.mu.Lock()
+	r.lastResult = result
+	r.mu.Unlock()
+}
+
+// Uptime is how long this Recorder (and so the process) has been running.
+func (r *Recorder) Uptime() time.Duration {
+	return time.Since(r.startedAt)
+}
+
+// Events returns a snapshot of recorded events, most recent first.
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ordered []Event
+	if r.filled {
+		ordered = make([]Event, len(r.events))
+		copy(ordered, r.events[r.next:])
+		copy(ordered[len(r.events)-r.next:], r.events[:r.next])
+	} else {
+		ordered = make([]Event, r.next)
+		copy(ordered, r.events[:r.next])
+	}
+
+	reversed := make([]Event, len(ordered))
+	for i, e := range ordered {
+		reversed[len(ordered)-1-i] = e
+	}
+	return reversed
+}
+
+// Snapshot is the /statusz JSON representation of a Recorder.
+type Snapshot struct {
+	State      string      `json:"state"`
+	Uptime     string      `json:"uptime"`
+	Watchlist  interface{} `json:"watchlist,omitempty"`
+	LastResult interface{} `json:"last_result,omitempty"`
+	Events     []EventJSON `json:"events"`
+}
+
+// EventJSON is Event with its level rendered as a string.
+type EventJSON struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+func (r *Recorder) Snapshot() Snapshot {
+	r.mu.Lock()
+	state, watchlist, lastResult := r.state, r.watchlist, r.lastResult
+	r.mu.Unlock()
+
+	events := r.Events()
+	eventsJSON := make([]EventJSON, len(events))
+	for i, e := range events {
+		eventsJSON[i] = EventJSON{Time: e.Time, Level: e.Level.String(), Message: e.Message}
+	}
+
+	return Snapshot{
+		State:      state,
+		Uptime:     r.Uptime().Round(time.Second).String(),
+		Watchlist:  watchlist,
+		LastResult: lastResult,
+		Events:     eventsJSON,
+	}
+}
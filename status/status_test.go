@@ -0,0 +1,56 @@
+package status
+
+import "testing"
+
+func messages(events []Event) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.Message
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEventsBeforeRingBufferFillsIsMostRecentFirst(t *testing.T) {
+	r := NewRecorder(5)
+	r.Infof("one")
+	r.Infof("two")
+	r.Infof("three")
+
+	got := messages(r.Events())
+	want := []string{"three", "two", "one"}
+	if !equal(got, want) {
+		t.Fatalf("Events() = %v, want %v", got, want)
+	}
+}
+
+func TestEventsAfterRingBufferWrapsIsMostRecentFirst(t *testing.T) {
+	r := NewRecorder(3)
+	for _, msg := range []string{"one", "two", "three", "four", "five"} {
+		r.Infof(msg)
+	}
+
+	got := messages(r.Events())
+	want := []string{"five", "four", "three"}
+	if !equal(got, want) {
+		t.Fatalf("Events() = %v, want %v", got, want)
+	}
+}
+
+func TestEventsEmptyRecorder(t *testing.T) {
+	r := NewRecorder(5)
+	if got := r.Events(); len(got) != 0 {
+		t.Fatalf("Events() on empty recorder = %v, want empty", got)
+	}
+}
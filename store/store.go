@@ -0,0 +1,194 @@
+// Package store is a small BoltDB-backed audit log for the launcher: every
+// launch attempt and every observed capacity snapshot is recorded so a
+// restart can reconcile against reality instead of blindly re-launching,
+// and so a launch-rate budget can be enforced across restarts.
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	launchesBucket  = []byte("launches")
+	snapshotsBucket = []byte("capacity_snapshots")
+)
+
+// snapshotRetention bounds how long capacity snapshots are kept. Snapshots
+// are recorded on every poll (every --check-interval, so thousands a day),
+// so without pruning the bucket would grow without bound for a long-running
+// daemon; a day of history is enough to explain recent launch decisions.
+const snapshotRetention = 24 * time.Hour
+
+// LaunchRecord is one attempt to launch an instance, successful or not.
+type LaunchRecord struct {
+	Time         time.Time   `json:"time"`
+	InstanceType string      `json:"instance_type"`
+	Region       string      `json:"region"`
+	Request      interface{} `json:"request"`
+	Response     interface{} `json:"response,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	InstanceIDs  []string    `json:"instance_ids,omitempty"`
+}
+
+// CapacitySnapshot is what instance-types reported available on one poll.
+type CapacitySnapshot struct {
+	Time      time.Time           `json:"time"`
+	Available map[string][]string `json:"available"` // instance type -> region names
+}
+
+// Store is a BoltDB-backed audit log, safe for concurrent use (BoltDB
+// serializes writes internally).
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (creating if needed) the BoltDB file at path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(launchesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(snapshotsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// RecordLaunch appends a launch attempt to the audit log.
+func (s *Store) RecordLaunch(rec LaunchRecord) error {
+	return s.put(launchesBucket, rec)
+}
+
+// RecordSnapshot appends an observed capacity snapshot to the audit log,
+// pruning anything older than snapshotRetention in the same transaction.
+func (s *Store) RecordSnapshot(snap CapacitySnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	cutoff := snap.Time.Add(-snapshotRetention)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(snapshotsBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := b.Put(sequenceKey(seq), data); err != nil {
+			return err
+		}
+		return pruneSnapshotsOlderThan(b, cutoff)
+	})
+}
+
+// pruneSnapshotsOlderThan deletes leading entries in b older than cutoff.
+// Sequence keys are assigned in insertion order, which is also time order
+// since RecordSnapshot always appends with the current time, so it's
+// sufficient to walk from the start and stop at the first survivor.
+func pruneSnapshotsOlderThan(b *bolt.Bucket, cutoff time.Time) error {
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		var snap CapacitySnapshot
+		if err := json.Unmarshal(v, &snap); err != nil {
+			return err
+		}
+		if !snap.Time.Before(cutoff) {
+			break
+		}
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) put(bucket []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(sequenceKey(seq), data)
+	})
+}
+
+// Launches returns every recorded launch attempt, oldest first.
+func (s *Store) Launches() ([]LaunchRecord, error) {
+	var records []LaunchRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(launchesBucket).ForEach(func(_, data []byte) error {
+			var rec LaunchRecord
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return err
+			}
+			records = append(records, rec)
+			return nil
+		})
+	})
+	return records, err
+}
+
+// Snapshots returns every capacity snapshot still within snapshotRetention,
+// oldest first.
+func (s *Store) Snapshots() ([]CapacitySnapshot, error) {
+	var snapshots []CapacitySnapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).ForEach(func(_, data []byte) error {
+			var snap CapacitySnapshot
+			if err := json.Unmarshal(data, &snap); err != nil {
+				return err
+			}
+			snapshots = append(snapshots, snap)
+			return nil
+		})
+	})
+	return snapshots, err
+}
+
+// InstancesLaunchedSince sums the instance count of every successful launch
+// recorded at or after since, for enforcing a MAX_LAUNCHES_PER_HOUR budget.
+func (s *Store) InstancesLaunchedSince(since time.Time) (int, error) {
+	count := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(launchesBucket).ForEach(func(_, data []byte) error {
+			var rec LaunchRecord
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return err
+			}
+			if rec.Error == "" && !rec.Time.Before(since) {
+				count += len(rec.InstanceIDs)
+			}
+			return nil
+		})
+	})
+	return count, err
+}
+
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSnapshotsReturnsRecorded(t *testing.T) {
+	s := openTestStore(t)
+
+	want := CapacitySnapshot{Time: time.Now(), Available: map[string][]string{"gpu_1x_a6000": {"us-east-1"}}}
+	if err := s.RecordSnapshot(want); err != nil {
+		t.Fatalf("RecordSnapshot: %v", err)
+	}
+
+	got, err := s.Snapshots()
+	if err != nil {
+		t.Fatalf("Snapshots: %v", err)
+	}
+	if len(got) != 1 || got[0].Available["gpu_1x_a6000"][0] != "us-east-1" {
+		t.Errorf("Snapshots() = %+v, want one snapshot matching %+v", got, want)
+	}
+}
+
+func TestRecordSnapshotPrunesOlderThanRetention(t *testing.T) {
+	s := openTestStore(t)
+
+	old := CapacitySnapshot{Time: time.Now().Add(-snapshotRetention - time.Hour), Available: map[string][]string{"old": {"r"}}}
+	if err := s.RecordSnapshot(old); err != nil {
+		t.Fatalf("RecordSnapshot(old): %v", err)
+	}
+
+	recent := CapacitySnapshot{Time: time.Now(), Available: map[string][]string{"new": {"r"}}}
+	if err := s.RecordSnapshot(recent); err != nil {
+		t.Fatalf("RecordSnapshot(recent): %v", err)
+	}
+
+	got, err := s.Snapshots()
+	if err != nil {
+		t.Fatalf("Snapshots: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Snapshots() = %+v, want only the non-expired snapshot", got)
+	}
+	if _, ok := got[0].Available["new"]; !ok {
+		t.Errorf("Snapshots() kept %+v, want the recent one, not the pruned old one", got[0])
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import "strings"
+
+// splitCSV splits a comma-separated value into trimmed, non-empty parts.
+func splitCSV(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}